@@ -4,6 +4,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Collection describes the period where a sensor and a datalogger are co-located at a site with the associated streams.
@@ -21,6 +22,14 @@ type Collection struct {
 
 	SensorCalibrations     []Calibration
 	DataloggerCalibrations []Calibration
+
+	polarity *Polarity
+}
+
+// Polarity returns the Polarity in effect over this Collection's Span, or
+// nil if no polarity record applies.
+func (c Collection) Polarity() *Polarity {
+	return c.polarity
 }
 
 // Less compares whether one Collection will sort before another.
@@ -75,38 +84,60 @@ func (c Collection) Code() string {
 	return c.Stream.Band + c.Stream.Source + c.Subsource()
 }
 
-// Dip returns the vertical orientation of the recorded stream in degrees from the vertical, positive values are downwards.
-func (c Collection) Dip(polarity *Polarity) float64 {
+// reversed reports whether polarity flips the sign of the recorded stream.
+func reversed(polarity *Polarity) bool {
+	return polarity != nil && polarity.Primary && polarity.Reversed
+}
 
-	// only adjust dips on vertical orientations (ignore inclined sensors for now)
-	if c.Component.Dip == 0.0 {
-		return 0.0
+// clampDip folds a dip value beyond +-90 once back across the pole, which
+// brings any value within [-270, 270] into [-90, 90], reporting whether the
+// value had to be folded across a pole. Nothing in meta validates that a
+// stored Component.Dip is within [-90, 90], so a folded dip is reachable
+// from malformed input data; a folded dip means the azimuth on the
+// opposite side of the sphere describes the same orientation, so the
+// caller needs to rotate it by 180 degrees to stay physically consistent.
+func clampDip(dip float64) (float64, bool) {
+	switch {
+	case dip > 90.0:
+		return 180.0 - dip, true
+	case dip < -90.0:
+		return -180.0 - dip, true
+	default:
+		return dip, false
 	}
+}
 
-	// dip based on the sensor configurati0on
+// Dip returns the vertical orientation of the recorded stream in degrees from the horizontal, positive values are downwards.
+func (c Collection) Dip(polarity *Polarity) float64 {
+
+	// dip based on the sensor configuration
 	dip := c.Component.Dip
 
 	// there may be a correction needed if the stream is considered reversed
-	if polarity != nil && polarity.Primary && polarity.Reversed {
+	if reversed(polarity) {
 		dip = -dip
-
 	}
 
+	dip, _ = clampDip(dip)
+
 	return dip
 }
 
 // Azimuth returns the horizontal orientation of the recorded stream in degrees from north.
 func (c Collection) Azimuth(polarity *Polarity) float64 {
 
-	// only adjust azimuth on horizontal orientations (ignore inclined sensors for now)
-	if c.Component.Dip != 0.0 {
-		return 0.0
-	}
-
 	// combine the sensor and the installed azimuths
 	azimuth := c.InstalledSensor.Azimuth + c.Component.Azimuth
 
-	if polarity != nil && polarity.Primary && polarity.Reversed {
+	dip := c.Component.Dip
+	if reversed(polarity) {
+		azimuth += 180.0
+		dip = -dip
+	}
+
+	// a dip that has to be folded back across a pole, with or without a
+	// reversal, describes the same orientation from the opposite azimuth
+	if _, folded := clampDip(dip); folded {
 		azimuth += 180.0
 	}
 
@@ -120,9 +151,20 @@ func (c Collection) Azimuth(polarity *Polarity) float64 {
 
 // Collections decodes the stored sensor and datalogger installation
 // times and builds a slice of overlapping time spans for the given site.
+//
+// Lookups against the Streams, Components, Gains, Calibrations and Channels
+// lists are served from indices built once per Set (see index), rather than
+// scanned linearly for every recorder or connection.
+//
+// Where the Polarity records for a Collection's (station, location,
+// subsource) subdivide its Span, the Collection is split into adjacent
+// polarity-homogeneous Collections (see splitByPolarity) so dip and azimuth
+// are never computed against a polarity that doesn't cover the whole span.
 func (s *Set) Collections(site Site) []Collection {
 	var collections []Collection
 
+	idx := s.index()
+
 	for _, recorder := range s.InstalledRecorders() {
 		if recorder.Station != site.Station {
 			continue
@@ -131,38 +173,15 @@ func (s *Set) Collections(site Site) []Collection {
 			continue
 		}
 
-		for _, stream := range s.Streams() {
-			if stream.Station != site.Station {
-				continue
-			}
-			if stream.Location != site.Location {
-				continue
-			}
-
+		for _, stream := range idx.streamsByStationLoc[stationLocKey{site.Station, site.Location}] {
 			span, ok := recorder.Span.Extent(stream.Span)
 			if !ok {
 				continue
 			}
 
-			for _, component := range s.Components() {
-				if recorder.InstalledSensor.Make != component.Make {
-					continue
-				}
-				if recorder.InstalledSensor.Model != component.Model {
-					continue
-				}
-
+			for _, component := range idx.componentsByMakeModel[makeModelKey{recorder.InstalledSensor.Make, recorder.InstalledSensor.Model}] {
 				var gains []Gain
-				for _, g := range s.Gains() {
-					if g.Station != stream.Station {
-						continue
-					}
-					if g.Location != stream.Location {
-						continue
-					}
-					if g.Subsource != component.Subsource {
-						continue
-					}
+				for _, g := range idx.gainsByStationLocSubsource[stationLocSubsourceKey{stream.Station, stream.Location, component.Subsource}] {
 					if !span.Overlaps(g.Span) {
 						continue
 					}
@@ -173,16 +192,7 @@ func (s *Set) Collections(site Site) []Collection {
 				})
 
 				var sensors []Calibration
-				for _, c := range s.Calibrations() {
-					if c.Make != recorder.InstalledSensor.Make {
-						continue
-					}
-					if c.Model != recorder.InstalledSensor.Model {
-						continue
-					}
-					if c.Serial != recorder.InstalledSensor.Serial {
-						continue
-					}
+				for _, c := range idx.calibrationsByMakeModelSerial[makeModelSerialKey{recorder.InstalledSensor.Make, recorder.InstalledSensor.Model, recorder.InstalledSensor.Serial}] {
 					if c.Number != component.Number {
 						continue
 					}
@@ -195,29 +205,13 @@ func (s *Set) Collections(site Site) []Collection {
 					return sensors[i].Span.Start.Before(sensors[j].Span.Start)
 				})
 
-				for _, channel := range s.Channels() {
-					if recorder.Make != channel.Make {
-						continue
-					}
-
-					if recorder.DataloggerModel != channel.Model {
-						continue
-					}
+				for _, channel := range idx.channelsByMakeModel[makeModelKey{recorder.Make, recorder.DataloggerModel}] {
 					if stream.SamplingRate != channel.SamplingRate {
 						continue
 					}
 
 					var dataloggers []Calibration
-					for _, c := range s.Calibrations() {
-						if c.Make != recorder.InstalledSensor.Make {
-							continue
-						}
-						if c.Model != recorder.DataloggerModel {
-							continue
-						}
-						if c.Serial != recorder.InstalledSensor.Serial {
-							continue
-						}
+					for _, c := range idx.calibrationsByMakeModelSerial[makeModelSerialKey{recorder.InstalledSensor.Make, recorder.DataloggerModel, recorder.InstalledSensor.Serial}] {
 						if c.Number != channel.Number {
 							continue
 						}
@@ -230,7 +224,7 @@ func (s *Set) Collections(site Site) []Collection {
 						return dataloggers[i].Span.Start.Before(dataloggers[j].Span.Start)
 					})
 
-					collections = append(collections, Collection{
+					collections = append(collections, s.splitByPolarity(idx, Collection{
 						InstalledSensor: recorder.InstalledSensor,
 						DeployedDatalogger: DeployedDatalogger{
 							Install: Install{
@@ -252,7 +246,7 @@ func (s *Set) Collections(site Site) []Collection {
 						Channel:                channel,
 						Component:              component,
 						Span:                   span,
-					})
+					})...)
 				}
 			}
 		}
@@ -287,38 +281,15 @@ func (s *Set) Collections(site Site) []Collection {
 					continue
 				}
 
-				for _, stream := range s.Streams() {
-					if stream.Station != site.Station {
-						continue
-					}
-					if stream.Location != site.Location {
-						continue
-					}
-
+				for _, stream := range idx.streamsByStationLoc[stationLocKey{site.Station, site.Location}] {
 					span, ok := span.Extent(stream.Span)
 					if !ok {
 						continue
 					}
 
-					for _, component := range s.Components() {
-						if sensor.Make != component.Make {
-							continue
-						}
-						if sensor.Model != component.Model {
-							continue
-						}
-
+					for _, component := range idx.componentsByMakeModel[makeModelKey{sensor.Make, sensor.Model}] {
 						var gains []Gain
-						for _, g := range s.Gains() {
-							if g.Station != stream.Station {
-								continue
-							}
-							if g.Location != stream.Location {
-								continue
-							}
-							if g.Subsource != component.Subsource {
-								continue
-							}
+						for _, g := range idx.gainsByStationLocSubsource[stationLocSubsourceKey{stream.Station, stream.Location, component.Subsource}] {
 							if !span.Overlaps(g.Span) {
 								continue
 							}
@@ -329,16 +300,7 @@ func (s *Set) Collections(site Site) []Collection {
 						})
 
 						var sensors []Calibration
-						for _, c := range s.Calibrations() {
-							if c.Make != sensor.Make {
-								continue
-							}
-							if c.Model != sensor.Model {
-								continue
-							}
-							if c.Serial != sensor.Serial {
-								continue
-							}
+						for _, c := range idx.calibrationsByMakeModelSerial[makeModelSerialKey{sensor.Make, sensor.Model, sensor.Serial}] {
 							if c.Number != component.Number {
 								continue
 							}
@@ -351,13 +313,7 @@ func (s *Set) Collections(site Site) []Collection {
 							return sensors[i].Span.Start.Before(sensors[j].Span.Start)
 						})
 
-						for _, channel := range s.Channels() {
-							if datalogger.Make != channel.Make {
-								continue
-							}
-							if datalogger.Model != channel.Model {
-								continue
-							}
+						for _, channel := range idx.channelsByMakeModel[makeModelKey{datalogger.Make, datalogger.Model}] {
 							if component.Number+connection.Number < channel.Number {
 								continue
 							}
@@ -367,16 +323,7 @@ func (s *Set) Collections(site Site) []Collection {
 							}
 
 							var dataloggers []Calibration
-							for _, c := range s.Calibrations() {
-								if c.Make != datalogger.Make {
-									continue
-								}
-								if c.Model != datalogger.Model {
-									continue
-								}
-								if c.Serial != datalogger.Serial {
-									continue
-								}
+							for _, c := range idx.calibrationsByMakeModelSerial[makeModelSerialKey{datalogger.Make, datalogger.Model, datalogger.Serial}] {
 								if c.Number != channel.Number {
 									continue
 								}
@@ -389,7 +336,7 @@ func (s *Set) Collections(site Site) []Collection {
 								return dataloggers[i].Span.Start.Before(dataloggers[j].Span.Start)
 							})
 
-							collections = append(collections, Collection{
+							collections = append(collections, s.splitByPolarity(idx, Collection{
 								InstalledSensor:        sensor,
 								DeployedDatalogger:     datalogger,
 								Stream:                 stream,
@@ -399,7 +346,7 @@ func (s *Set) Collections(site Site) []Collection {
 								Channel:                channel,
 								Component:              component,
 								Span:                   span,
-							})
+							})...)
 
 						}
 					}
@@ -413,4 +360,109 @@ func (s *Set) Collections(site Site) []Collection {
 	})
 
 	return collections
+}
+
+// splitByPolarity divides c into adjacent Collections, one per Polarity that
+// overlaps c's Span, plus a nil-Polarity Collection for any remaining
+// sub-span not covered by a Polarity record. Gains and calibrations are
+// re-filtered against each sub-span so they don't leak across boundaries.
+func (s *Set) splitByPolarity(idx *collectionIndex, c Collection) []Collection {
+	key := stationLocSubsourceKey{c.InstalledSensor.Station, c.InstalledSensor.Location, c.Component.Subsource}
+
+	var overlapping []Polarity
+	for _, p := range idx.polaritiesByStationLocSubsource[key] {
+		if c.Span.Overlaps(p.Span) {
+			overlapping = append(overlapping, p)
+		}
+	}
+	if len(overlapping) == 0 {
+		return []Collection{c}
+	}
+
+	sort.Slice(overlapping, func(i, j int) bool {
+		return overlapping[i].Span.Start.Before(overlapping[j].Span.Start)
+	})
+
+	var result []Collection
+	cursor := c.Span.Start
+
+	for i := range overlapping {
+		sub, ok := c.Span.Extent(overlapping[i].Span)
+		if !ok {
+			continue
+		}
+		// an open-ended Polarity record is cut short by the next one starting,
+		// so a later rewiring correction is never shadowed by an earlier record
+		// that was left open.
+		if i+1 < len(overlapping) {
+			sub.End = earliestNonZero(sub.End, overlapping[i+1].Span.Start)
+		}
+
+		if sub.Start.After(cursor) {
+			result = append(result, subCollection(c, Span{Start: cursor, End: sub.Start}, nil))
+		}
+
+		polarity := overlapping[i]
+		result = append(result, subCollection(c, sub, &polarity))
+
+		if sub.End.IsZero() {
+			return result
+		}
+		cursor = sub.End
+	}
+
+	if c.Span.End.IsZero() || cursor.Before(c.Span.End) {
+		result = append(result, subCollection(c, Span{Start: cursor, End: c.Span.End}, nil))
+	}
+
+	return result
+}
+
+// earliestNonZero returns the earlier of a and b, treating a zero time as
+// unbounded rather than as the zero value of the time line.
+func earliestNonZero(a, b time.Time) time.Time {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero():
+		return a
+	case a.Before(b):
+		return a
+	default:
+		return b
+	}
+}
+
+// subCollection copies c onto span with polarity attached, re-filtering its
+// Gains and calibrations so none of them leak outside span.
+func subCollection(c Collection, span Span, polarity *Polarity) Collection {
+	sub := c
+	sub.Span = span
+	sub.polarity = polarity
+	sub.Gains = filterGains(c.Gains, span)
+	sub.SensorCalibrations = filterCalibrations(c.SensorCalibrations, span)
+	sub.DataloggerCalibrations = filterCalibrations(c.DataloggerCalibrations, span)
+	return sub
+}
+
+// filterGains returns the Gains that overlap span.
+func filterGains(gains []Gain, span Span) []Gain {
+	var out []Gain
+	for _, g := range gains {
+		if span.Overlaps(g.Span) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// filterCalibrations returns the Calibrations that overlap span.
+func filterCalibrations(calibrations []Calibration, span Span) []Calibration {
+	var out []Calibration
+	for _, c := range calibrations {
+		if span.Overlaps(c.Span) {
+			out = append(out, c)
+		}
+	}
+	return out
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package meta
+
+// stationLocKey groups metadata by the station and location codes it applies to.
+type stationLocKey struct {
+	Station  string
+	Location string
+}
+
+// stationLocSubsourceKey groups metadata by station, location and subsource code.
+type stationLocSubsourceKey struct {
+	Station   string
+	Location  string
+	Subsource string
+}
+
+// makeModelKey groups equipment metadata by make and model.
+type makeModelKey struct {
+	Make  string
+	Model string
+}
+
+// makeModelSerialKey groups equipment metadata by make, model and serial number.
+type makeModelSerialKey struct {
+	Make   string
+	Model  string
+	Serial string
+}
+
+// collectionIndex holds the lookups Collections needs to avoid repeatedly
+// scanning the full Streams, Components, Gains, Calibrations, Channels and
+// Polarities lists.
+type collectionIndex struct {
+	streamsByStationLoc             map[stationLocKey][]Stream
+	componentsByMakeModel           map[makeModelKey][]Component
+	calibrationsByMakeModelSerial   map[makeModelSerialKey][]Calibration
+	gainsByStationLocSubsource      map[stationLocSubsourceKey][]Gain
+	channelsByMakeModel             map[makeModelKey][]Channel
+	polaritiesByStationLocSubsource map[stationLocSubsourceKey][]Polarity
+}
+
+// index lazily builds and caches the Collections lookups for s. The build
+// happens at most once, guarded by indexOnce, so concurrent calls from
+// multiple goroutines are safe.
+func (s *Set) index() *collectionIndex {
+	s.indexOnce.Do(func() {
+		idx := &collectionIndex{
+			streamsByStationLoc:             make(map[stationLocKey][]Stream),
+			componentsByMakeModel:           make(map[makeModelKey][]Component),
+			calibrationsByMakeModelSerial:   make(map[makeModelSerialKey][]Calibration),
+			gainsByStationLocSubsource:      make(map[stationLocSubsourceKey][]Gain),
+			channelsByMakeModel:             make(map[makeModelKey][]Channel),
+			polaritiesByStationLocSubsource: make(map[stationLocSubsourceKey][]Polarity),
+		}
+
+		for _, stream := range s.Streams() {
+			key := stationLocKey{stream.Station, stream.Location}
+			idx.streamsByStationLoc[key] = append(idx.streamsByStationLoc[key], stream)
+		}
+
+		for _, component := range s.Components() {
+			key := makeModelKey{component.Make, component.Model}
+			idx.componentsByMakeModel[key] = append(idx.componentsByMakeModel[key], component)
+		}
+
+		for _, calibration := range s.Calibrations() {
+			key := makeModelSerialKey{calibration.Make, calibration.Model, calibration.Serial}
+			idx.calibrationsByMakeModelSerial[key] = append(idx.calibrationsByMakeModelSerial[key], calibration)
+		}
+
+		for _, gain := range s.Gains() {
+			key := stationLocSubsourceKey{gain.Station, gain.Location, gain.Subsource}
+			idx.gainsByStationLocSubsource[key] = append(idx.gainsByStationLocSubsource[key], gain)
+		}
+
+		for _, channel := range s.Channels() {
+			key := makeModelKey{channel.Make, channel.Model}
+			idx.channelsByMakeModel[key] = append(idx.channelsByMakeModel[key], channel)
+		}
+
+		for _, polarity := range s.Polarities() {
+			key := stationLocSubsourceKey{polarity.Station, polarity.Location, polarity.Subsource}
+			idx.polaritiesByStationLocSubsource[key] = append(idx.polaritiesByStationLocSubsource[key], polarity)
+		}
+
+		s.collectionIdx = idx
+	})
+
+	return s.collectionIdx
+}
@@ -0,0 +1,90 @@
+package meta
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// buildBenchmarkSet returns a small synthetic network of the same shape as the
+// GeoNet network fixture, large enough to exercise the indexed lookups.
+func buildBenchmarkSet(b *testing.B) *Set {
+	b.Helper()
+
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var sites []Site
+	var installedRecorders []InstalledRecorder
+	var streams []Stream
+	var gains []Gain
+	var calibrations []Calibration
+
+	// A single shared equipment-type row per make/model, reused by every
+	// station that carries that instrument, mirrors real hardware: many
+	// stations share a sensor/datalogger model but each has its own serial.
+	components := []Component{{Equipment: Equipment{Make: "Trillium", Model: "T240"}, Number: "1", Subsource: "Z"}}
+	channels := []Channel{{Make: "Trillium", Model: "Q330", Number: "1", SamplingRate: 100}}
+
+	for i := 0; i < 200; i++ {
+		station := fmt.Sprintf("%04d", i)
+		site := Site{Station: station, Location: "01"}
+		sites = append(sites, site)
+
+		serial := fmt.Sprintf("%03d", i)
+
+		installedRecorders = append(installedRecorders, InstalledRecorder{
+			Equipment:       Equipment{Make: "Trillium", Model: "T240", Serial: serial},
+			InstalledSensor: InstalledSensor{Equipment: Equipment{Make: "Trillium", Model: "T240", Serial: serial}, Station: station, Location: "01", Span: Span{Start: start}},
+			DataloggerModel: "Q330",
+			Station:         station,
+			Location:        "01",
+			Span:            Span{Start: start},
+		})
+		streams = append(streams, Stream{Station: station, Location: "01", Band: "H", Source: "H", Axial: "false", SamplingRate: 100, Span: Span{Start: start}})
+		gains = append(gains, Gain{Station: station, Location: "01", Subsource: "Z", Gain: 1.0, Span: Span{Start: start}})
+		calibrations = append(calibrations, Calibration{Equipment: Equipment{Make: "Trillium", Model: "T240", Serial: serial}, Number: "1", Gain: 1.0, Span: Span{Start: start}})
+		calibrations = append(calibrations, Calibration{Equipment: Equipment{Make: "Trillium", Model: "Q330", Serial: serial}, Number: "1", Gain: 1.0, Span: Span{Start: start}})
+	}
+
+	set, err := NewSet(nil, sites, streams, components, channels, nil, nil, installedRecorders, nil, gains, calibrations, nil, nil, nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	return set
+}
+
+func BenchmarkSetCollections(b *testing.B) {
+	set := buildBenchmarkSet(b)
+	sites := set.Sites()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, site := range sites {
+			_ = set.Collections(site)
+		}
+	}
+}
+
+func TestSetIndexConcurrentAccess(t *testing.T) {
+	set, err := NewSet(nil,
+		[]Site{{Station: "ABCD", Location: "01"}},
+		[]Stream{{Station: "ABCD", Location: "01", Band: "H", Source: "H", Axial: "false"}},
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan *collectionIndex, 8)
+	for i := 0; i < cap(done); i++ {
+		go func() {
+			done <- set.index()
+		}()
+	}
+
+	first := <-done
+	for i := 1; i < cap(done); i++ {
+		if idx := <-done; idx != first {
+			t.Fatal("expected index() to return the same cached index to every goroutine")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package meta
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetCollectionsSplitsByPolarity(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	rewired := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	site := Site{Station: "ABCD", Location: "01"}
+
+	installedRecorders := []InstalledRecorder{{
+		Equipment:       Equipment{Make: "Trillium", Model: "T240", Serial: "001"},
+		InstalledSensor: InstalledSensor{Equipment: Equipment{Make: "Trillium", Model: "T240", Serial: "001"}, Station: "ABCD", Location: "01", Span: Span{Start: start, End: end}},
+		DataloggerModel: "Q330",
+		Station:         "ABCD",
+		Location:        "01",
+		Span:            Span{Start: start, End: end},
+	}}
+	streams := []Stream{{Station: "ABCD", Location: "01", Band: "H", Source: "H", Axial: "false", SamplingRate: 100, Span: Span{Start: start, End: end}}}
+	components := []Component{{Equipment: Equipment{Make: "Trillium", Model: "T240"}, Number: "1", Subsource: "Z"}}
+	channels := []Channel{{Make: "Trillium", Model: "Q330", Number: "1", SamplingRate: 100}}
+	polarities := []Polarity{
+		{Station: "ABCD", Location: "01", Subsource: "Z", Primary: true, Reversed: false, Span: Span{Start: start, End: rewired}},
+		{Station: "ABCD", Location: "01", Subsource: "Z", Primary: true, Reversed: true, Span: Span{Start: rewired, End: end}},
+	}
+
+	set, err := NewSet(nil, []Site{site}, streams, components, channels, nil, nil, installedRecorders, nil, nil, nil, polarities, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collections := set.Collections(site)
+	if len(collections) != 2 {
+		t.Fatalf("got %d collections, want 2 (one per polarity-homogeneous sub-span)", len(collections))
+	}
+
+	first, second := collections[0], collections[1]
+
+	if !first.Span.Start.Equal(start) || !first.Span.End.Equal(rewired) {
+		t.Fatalf("first sub-span = %v-%v, want %v-%v", first.Span.Start, first.Span.End, start, rewired)
+	}
+	if !second.Span.Start.Equal(rewired) || !second.Span.End.Equal(end) {
+		t.Fatalf("second sub-span = %v-%v, want %v-%v", second.Span.Start, second.Span.End, rewired, end)
+	}
+
+	if first.Polarity() == nil || first.Polarity().Reversed {
+		t.Fatalf("first Collection Polarity = %+v, want non-reversed", first.Polarity())
+	}
+	if second.Polarity() == nil || !second.Polarity().Reversed {
+		t.Fatalf("second Collection Polarity = %+v, want reversed", second.Polarity())
+	}
+
+	if first.Code() != second.Code() || first.Subsource() != second.Subsource() {
+		t.Fatalf("expected the two Collections to differ only in reversed flag, got Code %q/%q Subsource %q/%q",
+			first.Code(), second.Code(), first.Subsource(), second.Subsource())
+	}
+}
+
+// TestSetCollectionsSplitsByPolarityOpenEndedRecord covers a left-open
+// Polarity record (no End set) that a later record supersedes, as can happen
+// when an operator enters a rewiring correction without closing out the
+// previous one.
+func TestSetCollectionsSplitsByPolarityOpenEndedRecord(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	rewired := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	site := Site{Station: "ABCD", Location: "01"}
+
+	installedRecorders := []InstalledRecorder{{
+		Equipment:       Equipment{Make: "Trillium", Model: "T240", Serial: "001"},
+		InstalledSensor: InstalledSensor{Equipment: Equipment{Make: "Trillium", Model: "T240", Serial: "001"}, Station: "ABCD", Location: "01", Span: Span{Start: start, End: end}},
+		DataloggerModel: "Q330",
+		Station:         "ABCD",
+		Location:        "01",
+		Span:            Span{Start: start, End: end},
+	}}
+	streams := []Stream{{Station: "ABCD", Location: "01", Band: "H", Source: "H", Axial: "false", SamplingRate: 100, Span: Span{Start: start, End: end}}}
+	components := []Component{{Equipment: Equipment{Make: "Trillium", Model: "T240"}, Number: "1", Subsource: "Z"}}
+	channels := []Channel{{Make: "Trillium", Model: "Q330", Number: "1", SamplingRate: 100}}
+	polarities := []Polarity{
+		{Station: "ABCD", Location: "01", Subsource: "Z", Primary: true, Reversed: false, Span: Span{Start: start}},
+		{Station: "ABCD", Location: "01", Subsource: "Z", Primary: true, Reversed: true, Span: Span{Start: rewired, End: end}},
+	}
+
+	set, err := NewSet(nil, []Site{site}, streams, components, channels, nil, nil, installedRecorders, nil, nil, nil, polarities, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collections := set.Collections(site)
+	if len(collections) != 2 {
+		t.Fatalf("got %d collections, want 2 (the open-ended record must not shadow the later one)", len(collections))
+	}
+
+	first, second := collections[0], collections[1]
+
+	if !first.Span.End.Equal(rewired) {
+		t.Fatalf("first sub-span End = %v, want %v (cut short by the later record)", first.Span.End, rewired)
+	}
+	if first.Polarity() == nil || first.Polarity().Reversed {
+		t.Fatalf("first Collection Polarity = %+v, want non-reversed", first.Polarity())
+	}
+	if second.Polarity() == nil || !second.Polarity().Reversed {
+		t.Fatalf("second Collection Polarity = %+v, want reversed", second.Polarity())
+	}
+}
+
+// TestSetCollectionsSplitsByPolarityAxial covers an axial stream, whose
+// Collection.Subsource() remaps the raw component subsource (e.g. N -> 1),
+// while the Polarity and Gain records are keyed on the raw Component.Subsource.
+func TestSetCollectionsSplitsByPolarityAxial(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	rewired := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	site := Site{Station: "ABCD", Location: "01"}
+
+	installedRecorders := []InstalledRecorder{{
+		Equipment:       Equipment{Make: "Trillium", Model: "T240", Serial: "001"},
+		InstalledSensor: InstalledSensor{Equipment: Equipment{Make: "Trillium", Model: "T240", Serial: "001"}, Station: "ABCD", Location: "01", Span: Span{Start: start, End: end}},
+		DataloggerModel: "Q330",
+		Station:         "ABCD",
+		Location:        "01",
+		Span:            Span{Start: start, End: end},
+	}}
+	streams := []Stream{{Station: "ABCD", Location: "01", Band: "H", Source: "H", Axial: "true", SamplingRate: 100, Span: Span{Start: start, End: end}}}
+	components := []Component{{Equipment: Equipment{Make: "Trillium", Model: "T240"}, Number: "1", Subsource: "N"}}
+	channels := []Channel{{Make: "Trillium", Model: "Q330", Number: "1", SamplingRate: 100}}
+	polarities := []Polarity{
+		{Station: "ABCD", Location: "01", Subsource: "N", Primary: true, Reversed: false, Span: Span{Start: start, End: rewired}},
+		{Station: "ABCD", Location: "01", Subsource: "N", Primary: true, Reversed: true, Span: Span{Start: rewired, End: end}},
+	}
+
+	set, err := NewSet(nil, []Site{site}, streams, components, channels, nil, nil, installedRecorders, nil, nil, nil, polarities, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collections := set.Collections(site)
+	if len(collections) != 2 {
+		t.Fatalf("got %d collections, want 2 (axial Subsource remapping must not hide the Polarity match)", len(collections))
+	}
+
+	first, second := collections[0], collections[1]
+
+	if first.Polarity() == nil || first.Polarity().Reversed {
+		t.Fatalf("first Collection Polarity = %+v, want non-reversed", first.Polarity())
+	}
+	if second.Polarity() == nil || !second.Polarity().Reversed {
+		t.Fatalf("second Collection Polarity = %+v, want reversed", second.Polarity())
+	}
+}
@@ -0,0 +1,108 @@
+package meta
+
+import "testing"
+
+func TestCollectionDipAzimuth(t *testing.T) {
+	tests := []struct {
+		label        string
+		componentDip float64
+		componentAz  float64
+		sensorAz     float64
+		polarity     *Polarity
+		wantDip      float64
+		wantAzimuth  float64
+	}{
+		{
+			label:        "vertical",
+			componentDip: -90.0,
+			componentAz:  0.0,
+			sensorAz:     0.0,
+			wantDip:      -90.0,
+			wantAzimuth:  0.0,
+		},
+		{
+			label:        "vertical reversed",
+			componentDip: -90.0,
+			componentAz:  0.0,
+			sensorAz:     0.0,
+			polarity:     &Polarity{Primary: true, Reversed: true},
+			wantDip:      90.0,
+			wantAzimuth:  180.0,
+		},
+		{
+			label:        "horizontal",
+			componentDip: 0.0,
+			componentAz:  90.0,
+			sensorAz:     0.0,
+			wantDip:      0.0,
+			wantAzimuth:  90.0,
+		},
+		{
+			label:        "horizontal reversed",
+			componentDip: 0.0,
+			componentAz:  90.0,
+			sensorAz:     0.0,
+			polarity:     &Polarity{Primary: true, Reversed: true},
+			wantDip:      0.0,
+			wantAzimuth:  270.0,
+		},
+		{
+			label:        "45 degree inclined",
+			componentDip: 45.0,
+			componentAz:  30.0,
+			sensorAz:     0.0,
+			wantDip:      45.0,
+			wantAzimuth:  30.0,
+		},
+		{
+			label:        "45 degree inclined reversed",
+			componentDip: 45.0,
+			componentAz:  30.0,
+			sensorAz:     0.0,
+			polarity:     &Polarity{Primary: true, Reversed: true},
+			wantDip:      -45.0,
+			wantAzimuth:  210.0,
+		},
+		{
+			label:        "malformed dip above 90 degrees",
+			componentDip: 100.0,
+			componentAz:  30.0,
+			sensorAz:     0.0,
+			wantDip:      80.0,
+			wantAzimuth:  210.0,
+		},
+		{
+			label:        "malformed dip below -90 degrees",
+			componentDip: -100.0,
+			componentAz:  30.0,
+			sensorAz:     0.0,
+			wantDip:      -80.0,
+			wantAzimuth:  210.0,
+		},
+		{
+			label:        "malformed dip above 90 degrees reversed",
+			componentDip: 100.0,
+			componentAz:  30.0,
+			sensorAz:     0.0,
+			polarity:     &Polarity{Primary: true, Reversed: true},
+			wantDip:      -80.0,
+			wantAzimuth:  30.0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			c := Collection{
+				Component:       Component{Dip: test.componentDip, Azimuth: test.componentAz},
+				InstalledSensor: InstalledSensor{Azimuth: test.sensorAz},
+			}
+
+			if dip := c.Dip(test.polarity); dip != test.wantDip {
+				t.Errorf("Dip() = %v, want %v", dip, test.wantDip)
+			}
+			if azimuth := c.Azimuth(test.polarity); azimuth != test.wantAzimuth {
+				t.Errorf("Azimuth() = %v, want %v", azimuth, test.wantAzimuth)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package meta
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GaugeCollection describes the period where a tide gauge's harmonic
+// constituents apply, optionally paired with the Stream recording it.
+type GaugeCollection struct {
+	Span
+
+	Gauge
+	Stream
+
+	Constituents []Constituent
+}
+
+// Less compares whether one GaugeCollection will sort before another,
+// mirroring the station/location/start ordering used by Collection.Less.
+func (g GaugeCollection) Less(collection GaugeCollection) bool {
+	switch {
+	case g.Gauge.Station < collection.Gauge.Station:
+		return true
+	case g.Gauge.Station > collection.Gauge.Station:
+		return false
+	case g.Gauge.Location < collection.Gauge.Location:
+		return true
+	case g.Gauge.Location > collection.Gauge.Location:
+		return false
+	case g.Span.Start.Before(collection.Span.Start):
+		return true
+	default:
+		return false
+	}
+}
+
+// NewConstituentList validates a loaded set of tidal constituents, rejecting
+// duplicate (Gauge, Number) pairs.
+func NewConstituentList(constituents []Constituent) ([]Constituent, error) {
+	seen := make(map[string]bool)
+	for _, c := range constituents {
+		key := c.Gauge + "/" + c.Number
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate constituent %s/%s", c.Gauge, c.Number)
+		}
+		seen[key] = true
+	}
+	return constituents, nil
+}
+
+// GaugeCollections builds a slice of tide-gauge collections for the given
+// site, pairing each installed Gauge with the harmonic Constituents that
+// overlap its deployment span and, where one exists, the Stream recording it.
+func (s *Set) GaugeCollections(site Site) []GaugeCollection {
+	var collections []GaugeCollection
+
+	idx := s.index()
+
+	for _, gauge := range s.Gauges() {
+		if gauge.Station != site.Station {
+			continue
+		}
+		if gauge.Location != site.Location {
+			continue
+		}
+
+		var constituents []Constituent
+		for _, c := range s.Constituents() {
+			if c.Gauge != gauge.Code {
+				continue
+			}
+			if !gauge.Span.Overlaps(c.Span) {
+				continue
+			}
+			constituents = append(constituents, c)
+		}
+		sort.Slice(constituents, func(i, j int) bool {
+			return constituents[i].Number < constituents[j].Number
+		})
+
+		// sea-level streams are recorded on the long-period band ("L"); this
+		// excludes unrelated seismic streams (e.g. "HHZ") at the same site and
+		// requires the stream's Span to overlap the gauge's deployment.
+		var candidates []Stream
+		for _, candidate := range idx.streamsByStationLoc[stationLocKey{gauge.Station, gauge.Location}] {
+			if candidate.Band != "L" {
+				continue
+			}
+			if !gauge.Span.Overlaps(candidate.Span) {
+				continue
+			}
+			candidates = append(candidates, candidate)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].Span.Start.Before(candidates[j].Span.Start)
+		})
+
+		var stream Stream
+		if len(candidates) > 0 {
+			stream = candidates[0]
+		}
+
+		collections = append(collections, GaugeCollection{
+			Span:         gauge.Span,
+			Gauge:        gauge,
+			Stream:       stream,
+			Constituents: constituents,
+		})
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Less(collections[j])
+	})
+
+	return collections
+}
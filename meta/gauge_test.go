@@ -0,0 +1,85 @@
+package meta
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewConstituentListRejectsDuplicates(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	constituents := []Constituent{
+		{Gauge: "TAUP", Number: "M2", Amplitude: 1.0, Span: Span{Start: start}},
+		{Gauge: "TAUP", Number: "M2", Amplitude: 1.1, Span: Span{Start: start}},
+	}
+
+	if _, err := NewConstituentList(constituents); err == nil {
+		t.Fatal("expected an error for duplicate (Gauge, Number) pair")
+	}
+}
+
+func TestNewConstituentListAcceptsDistinctPairs(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	constituents := []Constituent{
+		{Gauge: "TAUP", Number: "M2", Amplitude: 1.0, Span: Span{Start: start}},
+		{Gauge: "TAUP", Number: "S2", Amplitude: 0.4, Span: Span{Start: start}},
+		{Gauge: "WLGT", Number: "M2", Amplitude: 0.9, Span: Span{Start: start}},
+	}
+
+	list, err := NewConstituentList(constituents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != len(constituents) {
+		t.Fatalf("got %d constituents, want %d", len(list), len(constituents))
+	}
+}
+
+func TestSetGaugeCollections(t *testing.T) {
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	site := Site{Station: "TAUP", Location: "01"}
+
+	gauges := []Gauge{
+		{Station: "TAUP", Location: "01", Code: "TAUP", Span: Span{Start: start, End: end}},
+	}
+	constituents := []Constituent{
+		{Gauge: "TAUP", Number: "M2", Doodson: "255.555", Darwin: "M2", Amplitude: 1.0, Phase: 120.0, Span: Span{Start: start, End: end}},
+		{Gauge: "TAUP", Number: "S2", Doodson: "273.555", Darwin: "S2", Amplitude: 0.4, Phase: 95.0, Span: Span{Start: start, End: end}},
+		{Gauge: "OTHR", Number: "M2", Amplitude: 2.0, Span: Span{Start: start, End: end}},
+		{Gauge: "TAUP", Number: "N2", Amplitude: 0.2, Span: Span{Start: end.AddDate(1, 0, 0), End: end.AddDate(2, 0, 0)}},
+	}
+	streams := []Stream{
+		// sorts before the sea-level stream below but is an unrelated seismic channel
+		{Station: "TAUP", Location: "01", Band: "H", Source: "H", Axial: "false", SamplingRate: 100, Span: Span{Start: start, End: end}},
+		// a sea-level stream that predates the gauge deployment and must not match
+		{Station: "TAUP", Location: "01", Band: "L", Source: "T", Axial: "false", SamplingRate: 1, Span: Span{Start: start.AddDate(-5, 0, 0), End: start.AddDate(0, 0, -1)}},
+		{Station: "TAUP", Location: "01", Band: "L", Source: "T", Axial: "false", SamplingRate: 1, Span: Span{Start: start}},
+	}
+
+	set, err := NewSet(nil, []Site{site}, streams, nil, nil, nil, nil, nil, nil, nil, nil, nil, gauges, constituents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collections := set.GaugeCollections(site)
+	if len(collections) != 1 {
+		t.Fatalf("got %d gauge collections, want 1", len(collections))
+	}
+
+	got := collections[0]
+	if len(got.Constituents) != 2 {
+		t.Fatalf("got %d constituents, want 2 (unrelated gauge and non-overlapping span excluded)", len(got.Constituents))
+	}
+	if got.Constituents[0].Number != "M2" || got.Constituents[1].Number != "S2" {
+		t.Fatalf("constituents not sorted by number: %+v", got.Constituents)
+	}
+	if got.Stream.Band != "L" {
+		t.Fatalf("got Stream.Band %q, want %q (must not match the unrelated seismic stream)", got.Stream.Band, "L")
+	}
+	if !got.Stream.Span.Start.Equal(start) {
+		t.Fatalf("got Stream.Span.Start %v, want %v (must not match the non-overlapping sea-level stream)", got.Stream.Span.Start, start)
+	}
+}
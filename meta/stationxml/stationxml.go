@@ -0,0 +1,342 @@
+// Package stationxml builds FDSN StationXML documents directly from a meta.Set,
+// replacing the ad-hoc station/channel derivation that used to live in external
+// build tooling.
+package stationxml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/EvanCGNS/delta/meta"
+)
+
+const (
+	// schemaVersion is the FDSN StationXML schema version this package emits.
+	schemaVersion = "1.1"
+	xmlns         = "http://www.fdsn.org/xml/station/1"
+)
+
+// Header carries the document level fields that identify who produced a StationXML file.
+type Header struct {
+	Source string
+	Sender string
+	Module string
+	URI    string
+}
+
+// Filter selects which networks, stations and channels are included in a build.
+// A nil regexp matches everything; a loaded list (even an empty one) restricts matches to the
+// given codes in addition to the regexp, mirroring the network/station/channel
+// list-file overrides supported by the original builder tool.
+type Filter struct {
+	Network *regexp.Regexp
+	Station *regexp.Regexp
+	Channel *regexp.Regexp
+
+	Stations map[string]bool
+	Channels map[string]bool
+}
+
+// NewFilter compiles network, station and channel regexps and, when given,
+// loads newline separated station and channel list files used to further
+// restrict the build.
+func NewFilter(network, station, channel, stationList, channelList string) (*Filter, error) {
+	var f Filter
+	var err error
+
+	if network != "" {
+		if f.Network, err = regexp.Compile(network); err != nil {
+			return nil, fmt.Errorf("invalid network filter %q: %v", network, err)
+		}
+	}
+	if station != "" {
+		if f.Station, err = regexp.Compile(station); err != nil {
+			return nil, fmt.Errorf("invalid station filter %q: %v", station, err)
+		}
+	}
+	if channel != "" {
+		if f.Channel, err = regexp.Compile(channel); err != nil {
+			return nil, fmt.Errorf("invalid channel filter %q: %v", channel, err)
+		}
+	}
+
+	if stationList != "" {
+		list, err := readList(stationList)
+		if err != nil {
+			return nil, err
+		}
+		f.Stations = list
+	}
+	if channelList != "" {
+		list, err := readList(channelList)
+		if err != nil {
+			return nil, err
+		}
+		f.Channels = list
+	}
+
+	return &f, nil
+}
+
+func readList(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			list[line] = true
+		}
+	}
+	return list, scanner.Err()
+}
+
+func (f *Filter) matchStation(net, sta string) bool {
+	if f == nil {
+		return true
+	}
+	if f.Network != nil && !f.Network.MatchString(net) {
+		return false
+	}
+	if f.Station != nil && !f.Station.MatchString(sta) {
+		return false
+	}
+	if f.Stations != nil && !f.Stations[sta] {
+		return false
+	}
+	return true
+}
+
+func (f *Filter) matchChannel(code string) bool {
+	if f == nil {
+		return true
+	}
+	if f.Channel != nil && !f.Channel.MatchString(code) {
+		return false
+	}
+	if f.Channels != nil && !f.Channels[code] {
+		return false
+	}
+	return true
+}
+
+// Build assembles an FDSNStationXML document from the given set of collections
+// for the selected sites, applying filter to restrict networks, stations and channels.
+// Polarities are resolved per Collection (see meta.Collection.Polarity) so reversed
+// streams flip dip and azimuth consistently, including across a mid-span rewiring.
+func Build(set *meta.Set, header Header, filter *Filter) (*FDSNStationXML, error) {
+	networks := make(map[string]*Network)
+	var order []string
+
+	for _, site := range set.Sites() {
+		if !filter.matchStation(site.Network, site.Station) {
+			continue
+		}
+
+		network, ok := networks[site.Network]
+		if !ok {
+			network = &Network{Code: site.Network}
+			networks[site.Network] = network
+			order = append(order, site.Network)
+		}
+
+		station, err := buildStation(set, site, filter)
+		if err != nil {
+			return nil, err
+		}
+		if station == nil {
+			continue
+		}
+
+		network.Stations = append(network.Stations, *station)
+	}
+
+	sort.Strings(order)
+
+	doc := &FDSNStationXML{
+		Xmlns:   xmlns,
+		Schema:  schemaVersion,
+		Source:  header.Source,
+		Sender:  header.Sender,
+		Module:  header.Module,
+		URI:     header.URI,
+		Created: meta.Now().Format(meta.DateTimeFormat),
+	}
+	for _, code := range order {
+		doc.Networks = append(doc.Networks, *networks[code])
+	}
+
+	return doc, nil
+}
+
+func buildStation(set *meta.Set, site meta.Site, filter *Filter) (*Station, error) {
+	collections := set.Collections(site)
+	if len(collections) == 0 {
+		return nil, nil
+	}
+
+	station := Station{
+		Code:      site.Station,
+		Latitude:  site.Latitude,
+		Longitude: site.Longitude,
+		Elevation: site.Elevation,
+	}
+
+	for _, c := range collections {
+		code := c.Code()
+		if !filter.matchChannel(code) {
+			continue
+		}
+
+		polarity := c.Polarity()
+
+		channel := Channel{
+			Code:         code,
+			LocationCode: c.InstalledSensor.Location,
+			StartDate:    c.Span.Start.Format(meta.DateTimeFormat),
+			Latitude:     site.Latitude,
+			Longitude:    site.Longitude,
+			Elevation:    site.Elevation,
+			Depth:        c.InstalledSensor.Depth,
+			Dip:          c.Dip(polarity),
+			Azimuth:      c.Azimuth(polarity),
+			SampleRate:   c.Stream.SamplingRate,
+			Response:     buildResponse(c),
+		}
+		if !c.Span.End.IsZero() {
+			channel.EndDate = c.Span.End.Format(meta.DateTimeFormat)
+		}
+
+		station.Channels = append(station.Channels, channel)
+	}
+
+	if len(station.Channels) == 0 {
+		return nil, nil
+	}
+
+	return &station, nil
+}
+
+// buildResponse assembles a Response from the sensor and datalogger calibrations
+// and gains attached to a Collection, mirroring the stage ordering of a real
+// sensor -> datalogger signal chain.
+func buildResponse(c meta.Collection) Response {
+	var response Response
+	var stages []Stage
+
+	for _, s := range c.SensorCalibrations {
+		stages = append(stages, Stage{
+			Number:      len(stages) + 1,
+			Gain:        s.Gain,
+			InputUnits:  "m/s",
+			OutputUnits: "V",
+		})
+	}
+
+	for _, g := range c.Gains {
+		stages = append(stages, Stage{
+			Number:      len(stages) + 1,
+			Gain:        g.Gain,
+			InputUnits:  "V",
+			OutputUnits: "counts",
+		})
+	}
+
+	for _, d := range c.DataloggerCalibrations {
+		stages = append(stages, Stage{
+			Number:      len(stages) + 1,
+			Gain:        d.Gain,
+			InputUnits:  "V",
+			OutputUnits: "counts",
+		})
+	}
+
+	sensitivity := 1.0
+	for _, s := range stages {
+		if s.Gain != 0 {
+			sensitivity *= s.Gain
+		}
+	}
+
+	response.Stages = stages
+	response.InstrumentSensitivity = InstrumentSensitivity{
+		Value:       sensitivity,
+		InputUnits:  "m/s",
+		OutputUnits: "counts",
+	}
+
+	return response
+}
+
+// FDSNStationXML is the document root of an FDSN StationXML 1.1 file.
+type FDSNStationXML struct {
+	XMLName  xml.Name  `xml:"FDSNStationXML"`
+	Xmlns    string    `xml:"xmlns,attr"`
+	Schema   string    `xml:"schemaVersion,attr"`
+	Source   string    `xml:"Source"`
+	Sender   string    `xml:"Sender,omitempty"`
+	Module   string    `xml:"Module,omitempty"`
+	URI      string    `xml:"ModuleURI,omitempty"`
+	Created  string    `xml:"Created"`
+	Networks []Network `xml:"Network"`
+}
+
+// Network is the FDSN StationXML Network element.
+type Network struct {
+	Code     string    `xml:"code,attr"`
+	Stations []Station `xml:"Station"`
+}
+
+// Station is the FDSN StationXML Station element.
+type Station struct {
+	Code      string    `xml:"code,attr"`
+	Latitude  float64   `xml:"Latitude"`
+	Longitude float64   `xml:"Longitude"`
+	Elevation float64   `xml:"Elevation"`
+	Channels  []Channel `xml:"Channel"`
+}
+
+// Channel is the FDSN StationXML Channel element.
+type Channel struct {
+	Code         string   `xml:"code,attr"`
+	LocationCode string   `xml:"locationCode,attr"`
+	StartDate    string   `xml:"startDate,attr"`
+	EndDate      string   `xml:"endDate,attr,omitempty"`
+	Latitude     float64  `xml:"Latitude"`
+	Longitude    float64  `xml:"Longitude"`
+	Elevation    float64  `xml:"Elevation"`
+	Depth        float64  `xml:"Depth"`
+	Azimuth      float64  `xml:"Azimuth"`
+	Dip          float64  `xml:"Dip"`
+	SampleRate   float64  `xml:"SampleRate"`
+	Response     Response `xml:"Response"`
+}
+
+// Response is the FDSN StationXML Response element.
+type Response struct {
+	InstrumentSensitivity InstrumentSensitivity `xml:"InstrumentSensitivity"`
+	Stages                []Stage               `xml:"Stage"`
+}
+
+// InstrumentSensitivity is the FDSN StationXML InstrumentSensitivity element.
+type InstrumentSensitivity struct {
+	Value       float64 `xml:"Value"`
+	InputUnits  string  `xml:"InputUnits>Name"`
+	OutputUnits string  `xml:"OutputUnits>Name"`
+}
+
+// Stage is a single stage of an FDSN StationXML Response.
+type Stage struct {
+	Number      int     `xml:"number,attr"`
+	Gain        float64 `xml:"StageGain>Value"`
+	InputUnits  string  `xml:"InputUnits>Name"`
+	OutputUnits string  `xml:"OutputUnits>Name"`
+}
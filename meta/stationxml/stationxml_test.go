@@ -0,0 +1,91 @@
+package stationxml
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/EvanCGNS/delta/meta"
+)
+
+// buildFixtureSet returns a minimal single station, single channel network.
+func buildFixtureSet(t *testing.T) *meta.Set {
+	t.Helper()
+
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sites := []meta.Site{{Network: "NZ", Station: "ABCD", Location: "01", Latitude: -41.0, Longitude: 174.0, Elevation: 100}}
+	installedRecorders := []meta.InstalledRecorder{{
+		Equipment:       meta.Equipment{Make: "Trillium", Model: "T240", Serial: "001"},
+		InstalledSensor: meta.InstalledSensor{Equipment: meta.Equipment{Make: "Trillium", Model: "T240", Serial: "001"}, Station: "ABCD", Location: "01", Span: meta.Span{Start: start}},
+		DataloggerModel: "Q330",
+		Station:         "ABCD",
+		Location:        "01",
+		Span:            meta.Span{Start: start},
+	}}
+	streams := []meta.Stream{{Station: "ABCD", Location: "01", Band: "H", Source: "H", Axial: "false", SamplingRate: 100, Span: meta.Span{Start: start}}}
+	components := []meta.Component{{Equipment: meta.Equipment{Make: "Trillium", Model: "T240"}, Number: "1", Subsource: "Z"}}
+	channels := []meta.Channel{{Make: "Trillium", Model: "Q330", Number: "1", SamplingRate: 100}}
+	gains := []meta.Gain{{Station: "ABCD", Location: "01", Subsource: "Z", Gain: 1.0, Span: meta.Span{Start: start}}}
+	calibrations := []meta.Calibration{
+		{Equipment: meta.Equipment{Make: "Trillium", Model: "T240", Serial: "001"}, Number: "1", Gain: 1.0, Span: meta.Span{Start: start}},
+		{Equipment: meta.Equipment{Make: "Trillium", Model: "Q330", Serial: "001"}, Number: "1", Gain: 1.0, Span: meta.Span{Start: start}},
+	}
+
+	set, err := meta.NewSet(nil, sites, streams, components, channels, nil, nil, installedRecorders, nil, gains, calibrations, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return set
+}
+
+func TestBuild(t *testing.T) {
+	set := buildFixtureSet(t)
+
+	doc, err := Build(set, Header{Source: "Delta", Sender: "GNS", Module: "delta-stationxml"}, nil)
+	if err != nil {
+		t.Fatalf("unable to build StationXML: %v", err)
+	}
+	if len(doc.Networks) == 0 {
+		t.Fatal("expected at least one network in built StationXML")
+	}
+
+	raw, err := xml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unable to marshal StationXML: %v", err)
+	}
+
+	var reparsed FDSNStationXML
+	if err := xml.Unmarshal(raw, &reparsed); err != nil {
+		t.Fatalf("unable to round-trip StationXML: %v", err)
+	}
+
+	if len(reparsed.Networks) != len(doc.Networks) {
+		t.Fatalf("round trip mismatch: got %d networks, expected %d", len(reparsed.Networks), len(doc.Networks))
+	}
+	for i, net := range reparsed.Networks {
+		if net.Code != doc.Networks[i].Code {
+			t.Errorf("network %d: got code %q, expected %q", i, net.Code, doc.Networks[i].Code)
+		}
+		if len(net.Stations) != len(doc.Networks[i].Stations) {
+			t.Errorf("network %d: got %d stations, expected %d", i, len(net.Stations), len(doc.Networks[i].Stations))
+		}
+	}
+}
+
+func TestFilterStation(t *testing.T) {
+	filter, err := NewFilter("^NZ$", "^ABCD$", "", "", "")
+	if err != nil {
+		t.Fatalf("unable to build filter: %v", err)
+	}
+
+	if !filter.matchStation("NZ", "ABCD") {
+		t.Error("expected filter to match NZ.ABCD")
+	}
+	if filter.matchStation("NZ", "WXYZ") {
+		t.Error("expected filter to reject NZ.WXYZ")
+	}
+	if filter.matchStation("AU", "ABCD") {
+		t.Error("expected filter to reject AU.ABCD")
+	}
+}
@@ -0,0 +1,257 @@
+package meta
+
+import (
+	"sync"
+	"time"
+)
+
+// DateTimeFormat is the timestamp layout used throughout exported metadata.
+const DateTimeFormat = "2006-01-02T15:04:05Z"
+
+// Now returns the current time, used when stamping generated documents.
+func Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Span represents a time interval over which a piece of metadata applies.
+// A zero End means the span is still open.
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps reports whether two spans share any time in common.
+func (s Span) Overlaps(span Span) bool {
+	if !s.End.IsZero() && span.Start.After(s.End) {
+		return false
+	}
+	if !span.End.IsZero() && s.Start.After(span.End) {
+		return false
+	}
+	return true
+}
+
+// Extent returns the intersection of s with one or more other spans, and
+// false if they do not all overlap.
+func (s Span) Extent(spans ...Span) (Span, bool) {
+	extent := s
+	for _, span := range spans {
+		if !extent.Overlaps(span) {
+			return Span{}, false
+		}
+		if span.Start.After(extent.Start) {
+			extent.Start = span.Start
+		}
+		if !span.End.IsZero() && (extent.End.IsZero() || span.End.Before(extent.End)) {
+			extent.End = span.End
+		}
+	}
+	return extent, true
+}
+
+// Equipment identifies a physical piece of hardware by make, model and serial number.
+type Equipment struct {
+	Make   string
+	Model  string
+	Serial string
+}
+
+// Install pairs an Equipment with the Span over which it was deployed.
+type Install struct {
+	Equipment
+	Span
+}
+
+// Network is a collection of sites sharing a network code.
+type Network struct {
+	Code        string
+	Description string
+}
+
+// Site is a physical location hosting installed equipment.
+type Site struct {
+	Network   string
+	Station   string
+	Location  string
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+}
+
+// Stream describes a single recorded data stream at a site.
+type Stream struct {
+	Station      string
+	Location     string
+	Band         string
+	Source       string
+	Axial        string
+	SamplingRate float64
+	Span
+}
+
+// Component is a single sensor component, such as one axis of a seismometer.
+type Component struct {
+	Equipment
+	Number    string
+	Subsource string
+	Dip       float64
+	Azimuth   float64
+}
+
+// Channel describes one recorder channel that a stream may be recorded through.
+type Channel struct {
+	Make         string
+	Model        string
+	Number       string
+	SamplingRate float64
+}
+
+// InstalledSensor is a sensor installed at a site for some span.
+type InstalledSensor struct {
+	Equipment
+	Station  string
+	Location string
+	Depth    float64
+	Azimuth  float64
+	Dip      float64
+	Span
+}
+
+// DeployedDatalogger is a datalogger deployed at a recording place for some span.
+type DeployedDatalogger struct {
+	Install
+	Place string
+	Role  string
+}
+
+// InstalledRecorder pairs a sensor and a datalogger installed together at a site.
+type InstalledRecorder struct {
+	Equipment
+	InstalledSensor
+	DataloggerModel string
+	Station         string
+	Location        string
+	Span
+}
+
+// Connection links a deployed datalogger to the sensor it records through a numbered input.
+type Connection struct {
+	Station  string
+	Location string
+	Place    string
+	Role     string
+	Number   string
+	Span
+}
+
+// Gain is a recorded gain value applying to a station/location/subsource over a span.
+type Gain struct {
+	Station   string
+	Location  string
+	Subsource string
+	Gain      float64
+	Span
+}
+
+// Calibration is a recorded response calibration for a numbered equipment component over a span.
+type Calibration struct {
+	Equipment
+	Number string
+	Gain   float64
+	Span
+}
+
+// Polarity records whether a station/location/subsource stream is wired in reverse over a span.
+type Polarity struct {
+	Station   string
+	Location  string
+	Subsource string
+	Primary   bool
+	Reversed  bool
+	Span
+}
+
+// Gauge is a tide gauge installed at a site for some span.
+type Gauge struct {
+	Station  string
+	Location string
+	Code     string
+	Span
+}
+
+// Constituent is a harmonic tidal constituent fitted for a Gauge over some span.
+type Constituent struct {
+	Gauge     string
+	Number    string
+	Doodson   string
+	Darwin    string
+	Amplitude float64
+	Phase     float64
+	Span
+}
+
+// Set is the collected, indexed view of all metadata lists loaded for a network.
+type Set struct {
+	networks            []Network
+	sites               []Site
+	streams             []Stream
+	components          []Component
+	channels            []Channel
+	installedSensors    []InstalledSensor
+	deployedDataloggers []DeployedDatalogger
+	installedRecorders  []InstalledRecorder
+	connections         []Connection
+	gains               []Gain
+	calibrations        []Calibration
+	polarities          []Polarity
+	gauges              []Gauge
+	constituents        []Constituent
+
+	indexOnce     sync.Once
+	collectionIdx *collectionIndex
+}
+
+// NewSet builds a Set from already decoded metadata lists, as used by tests
+// and fixtures that do not load from CSV. It rejects constituents with a
+// duplicate (Gauge, Number) pair.
+func NewSet(networks []Network, sites []Site, streams []Stream, components []Component, channels []Channel,
+	installedSensors []InstalledSensor, deployedDataloggers []DeployedDatalogger, installedRecorders []InstalledRecorder,
+	connections []Connection, gains []Gain, calibrations []Calibration, polarities []Polarity,
+	gauges []Gauge, constituents []Constituent) (*Set, error) {
+	constituents, err := NewConstituentList(constituents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Set{
+		networks:            networks,
+		sites:               sites,
+		streams:             streams,
+		components:          components,
+		channels:            channels,
+		installedSensors:    installedSensors,
+		deployedDataloggers: deployedDataloggers,
+		installedRecorders:  installedRecorders,
+		connections:         connections,
+		gains:               gains,
+		calibrations:        calibrations,
+		polarities:          polarities,
+		gauges:              gauges,
+		constituents:        constituents,
+	}, nil
+}
+
+func (s *Set) Networks() []Network                       { return s.networks }
+func (s *Set) Sites() []Site                             { return s.sites }
+func (s *Set) Streams() []Stream                         { return s.streams }
+func (s *Set) Components() []Component                   { return s.components }
+func (s *Set) Channels() []Channel                       { return s.channels }
+func (s *Set) InstalledSensors() []InstalledSensor       { return s.installedSensors }
+func (s *Set) DeployedDataloggers() []DeployedDatalogger { return s.deployedDataloggers }
+func (s *Set) InstalledRecorders() []InstalledRecorder   { return s.installedRecorders }
+func (s *Set) Connections() []Connection                 { return s.connections }
+func (s *Set) Gains() []Gain                             { return s.gains }
+func (s *Set) Calibrations() []Calibration               { return s.calibrations }
+func (s *Set) Polarities() []Polarity                    { return s.polarities }
+func (s *Set) Gauges() []Gauge                           { return s.gauges }
+func (s *Set) Constituents() []Constituent               { return s.constituents }